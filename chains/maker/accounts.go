@@ -14,6 +14,31 @@ import (
 	ptypes "github.com/eris-ltd/eris-db/permission/types"
 )
 
+// AccountType wraps a *definitions.ErisDBAccountType with the fields this
+// package needs that are not (and, as of this writing, cannot be) declared
+// on the upstream eris/definitions type itself: KeyScheme, SignerBackend,
+// SignerAddress and Roles. Keeping them here instead of assuming they exist
+// on the vendored struct means this package stays buildable against
+// whatever definitions.ErisDBAccountType actually ships with, and manifest
+// loading, the presets and MakeAccounts all read and write these through
+// one place.
+type AccountType struct {
+	*definitions.ErisDBAccountType
+
+	// Roles is passed to ptypes.ConvertPermissionsMapAndRolesToAccountPermissions
+	// alongside Perms.
+	Roles []string
+	// KeyScheme selects the registered scheme (see keyscheme.go) this
+	// account type's key is generated and addressed with; empty defaults
+	// to "ed25519,ripemd160".
+	KeyScheme string
+	// SignerBackend and SignerAddress select how a validator account's key
+	// is provisioned; see SignerBackend (the interface, in signer.go) and
+	// signerBackendFor.
+	SignerBackend string
+	SignerAddress string
+}
+
 // ErisDBAccountConstructor contains different views on a single account
 // for the purpose of constructing the configuration, genesis, and private
 // validator file.
@@ -35,13 +60,28 @@ type ErisDBAccountConstructor struct {
 	// NOTE: [ben] because this is bad practice, it now requires explicit
 	// flag `eris chains make --unsafe` (unsafe bool in signatures below)
 	untypedPrivateKeyBytes []byte
+
+	// derivationPath records the BIP32/BIP44 path this account's key was
+	// derived at when MakeAccounts was given a mnemonic; empty otherwise.
+	derivationPath string `json:"derivation_path,omitempty"`
+
+	// validatorPubKeyType records the KeyScheme's GenesisValidatorPubKeyType
+	// this account's GenesisValidator (if any) was built with, so that
+	// GenerateGenesisFileBytes can derive consensus_params.validator_pub_key_types
+	// instead of assuming every validator uses ed25519.
+	validatorPubKeyType string
 }
 
 // MakeAccounts specifies the chaintype and chain name and creates the constructors for generating
 // configuration, genesis and private validator files (the latter if required - for development purposes)
 // NOTE: [ben] if unsafe is set to true the private keys will be extracted from eris-keys and be written
 // into accounts.json. This will be deprecated in v0.17
-func MakeAccounts(name, chainType string, accountTypes []*definitions.ErisDBAccountType, unsafe bool) ([]*ErisDBAccountConstructor, error) {
+// If mnemonic is non-empty, every account's key is derived deterministically from it over BIP32/BIP44
+// rather than generated at random, so repeated calls with the same mnemonic and accountTypes produce the
+// same keys and addresses, and a byte-identical genesis when GenerateGenesisFileBytes is given a fixed
+// GenesisOptions.GenesisTime (it defaults to the current time). This currently requires every accountType to use the secp256k1,keccak256
+// KeyScheme; see deriveKeyPair for why ed25519 is not supported yet.
+func MakeAccounts(name, chainType string, accountTypes []*AccountType, unsafe bool, mnemonic string) ([]*ErisDBAccountConstructor, error) {
 
 	accountConstructors := []*ErisDBAccountConstructor{}
 
@@ -50,7 +90,7 @@ func MakeAccounts(name, chainType string, accountTypes []*definitions.ErisDBAcco
 	// and currently Tendermint is the only consensus engine (chain) that is supported.  As such the variable
 	// "chainType" can be misleading.
 	case "mint":
-		for _, accountType := range accountTypes {
+		for accountTypeIndex, accountType := range accountTypes {
 			log.WithField("type", accountType.Name).Info("Making Account Type")
 			for i := 0; i < accountType.Number; i++ {
 				// account names are formatted <ChainName_AccountTypeName_nnn>
@@ -61,10 +101,21 @@ func MakeAccounts(name, chainType string, accountTypes []*definitions.ErisDBAcco
 				// NOTE: [ben] for v0.16 we get the private validator file if `ToBond` > 0
 				// For v0.17 we will default to all validators only using remote signing,
 				// and then we should block by default extraction of private validator file.
-				// NOTE: [ben] currently we default to ed25519/SHA512 for PKI and ripemd16
-				// for address calculation.
-				accountConstructor, err := newErisDBAccountConstructor(accountName, "ed25519,ripemd160",
-					accountType, false, unsafe)
+				// keyAddressType defaults to ed25519/SHA512 for PKI and ripemd160 for
+				// address calculation, unless the account type opts into another
+				// registered KeyScheme (e.g. "secp256k1,keccak256").
+				keyAddressType := accountType.KeyScheme
+				if keyAddressType == "" {
+					keyAddressType = "ed25519,ripemd160"
+				}
+
+				var derivationPath string
+				if mnemonic != "" {
+					derivationPath = hdDerivationPath(DefaultHDCoinType, accountTypeIndex, i)
+				}
+
+				accountConstructor, err := newErisDBAccountConstructor(accountName, keyAddressType,
+					accountType, false, unsafe, mnemonic, derivationPath)
 				if err != nil {
 					return nil, fmt.Errorf("Failed to construct account %s for %s", accountName, name)
 				}
@@ -82,47 +133,76 @@ func MakeAccounts(name, chainType string, accountTypes []*definitions.ErisDBAcco
 // helper functions for MakeAccounts
 
 // newErisDBAccountConstructor returns an ErisDBAccountConstructor that has a GenesisAccount
-// and depending on the AccountType returns a GenesisValidator.  If a private validator file
-// is needed for a validating account, it will pull the private key, unless this is
-// explicitly blocked.
+// and depending on the AccountType returns a GenesisValidator.  Validator identities are
+// provisioned through the SignerBackend selected by accountType (eris-keys by default); only
+// the eris-keys backend is able to pull a private validator file, and then only when this is
+// not explicitly blocked.
+// If mnemonic is non-empty, the account's key is derived deterministically at derivationPath
+// instead of being generated by a SignerBackend or eris-keys, and no private validator file is
+// produced since the key can always be rederived from the mnemonic and path.
 func newErisDBAccountConstructor(accountName string, keyAddressType string,
-	accountType *definitions.ErisDBAccountType, blockPrivateValidator, unsafe bool) (*ErisDBAccountConstructor, error) {
+	accountType *AccountType, blockPrivateValidator, unsafe bool,
+	mnemonic, derivationPath string) (*ErisDBAccountConstructor, error) {
 
 	var err error
 	isValidator := (accountType.ToBond > 0 && accountType.Tokens >= accountType.ToBond)
 	accountConstructor := &ErisDBAccountConstructor{}
 	var genesisPrivateValidator *genesis.GenesisPrivateValidator
 	permissions := &ptypes.AccountPermissions{}
-	// TODO: expose roles
-	// convert the permissions map of string-integer pairs to an
-	// AccountPermissions type.
+	// convert the permissions map of string-integer pairs and the account
+	// type's roles into an AccountPermissions type.
 	if permissions, err = ptypes.ConvertPermissionsMapAndRolesToAccountPermissions(
-		accountType.Perms, []string{}); err != nil {
+		accountType.Perms, accountType.Roles); err != nil {
 		return nil, err
 	}
+	keyScheme, err := keySchemeFor(keyAddressType)
+	if err != nil {
+		return nil, err
+	}
+
 	var address, publicKeyBytes []byte
-	switch keyAddressType {
-	// use ed25519/SHA512 for PKI and ripemd160 for Address
-	case "ed25519,ripemd160":
+	switch {
+	case mnemonic != "":
+		if address, publicKeyBytes, err = deriveKeyPair(mnemonic, derivationPath, keyAddressType); err != nil {
+			return nil, err
+		}
+		accountConstructor.derivationPath = derivationPath
+	case isValidator:
+		// NOTE: validator accounts are provisioned through a SignerBackend so
+		// that, in production, the private key never needs to leave an
+		// eris-keys process, a Tendermint remote-signer, or an HTTP KMS.
+		signerBackend, signerErr := signerBackendFor(accountType)
+		if signerErr != nil {
+			return nil, signerErr
+		}
+		if address, publicKeyBytes, genesisPrivateValidator, err = signerBackend.GenerateAddressAndKey(
+			keyAddressType, blockPrivateValidator); err != nil {
+			return nil, err
+		}
+	default:
 		if address, publicKeyBytes, genesisPrivateValidator, err = generateAddressAndKey(
 			keyAddressType, blockPrivateValidator); err != nil {
 			return nil, err
 		}
+	}
 
-		// NOTE: [ben] these auxiliary fields in the constructor are to be deprecated
-		// but introduced to support current unsafe behaviour where all private keys
-		// are extracted from eris-keys
-		copy(accountConstructor.untypedPublicKeyBytes, publicKeyBytes)
-		// tendermint/go-crypto typebyte for ed25519
-		accountConstructor.typeBytePublicKey = byte(0x01)
-
-		if unsafe {
-			copy(accountConstructor.untypedPrivateKeyBytes, genesisPrivateValidator.PrivKey.Bytes())
+	// a scheme that derives its own address from the public key (e.g.
+	// secp256k1,keccak256) takes precedence over whatever the signer
+	// backend reported, so the address is always consistent with keyScheme.
+	if keyScheme.AddressFromPublicKey != nil {
+		if address, err = keyScheme.AddressFromPublicKey(publicKeyBytes); err != nil {
+			return nil, err
 		}
-	default:
-		// the other code paths in eris-keys are currently not tested for;
-		return nil, fmt.Errorf("Currently only supported ed265519/ripemd160: unknown key type (%s)",
-			keyAddressType)
+	}
+
+	// NOTE: [ben] these auxiliary fields in the constructor are to be deprecated
+	// but introduced to support current unsafe behaviour where all private keys
+	// are extracted from eris-keys
+	accountConstructor.untypedPublicKeyBytes = append([]byte(nil), publicKeyBytes...)
+	accountConstructor.typeBytePublicKey = keyScheme.TypeBytePublicKey
+
+	if unsafe && genesisPrivateValidator != nil {
+		accountConstructor.untypedPrivateKeyBytes = append([]byte(nil), genesisPrivateValidator.PrivKey.Bytes()...)
 	}
 
 	accountConstructor.genesisAccount = genesis.NewGenesisAccount(
@@ -146,18 +226,19 @@ func newErisDBAccountConstructor(accountName string, keyAddressType string,
 			address,
 			// Genesis validator bond amount
 			int64(accountType.ToBond),
-			// Genesis validator public key type string
-			// Currently only ed22519 is exposed through the tooling
-			"ed25519",
+			// Genesis validator public key type string, as declared by
+			// the account's KeyScheme (e.g. "ed25519" or "secp256k1")
+			keyScheme.GenesisValidatorPubKeyType,
 			// Genesis validator public key bytes
 			publicKeyBytes)
+		accountConstructor.validatorPubKeyType = keyScheme.GenesisValidatorPubKeyType
 		if err != nil {
 			return nil, err
 		}
 
 		if genesisPrivateValidator != nil && !blockPrivateValidator {
 			// explicitly copy genesis private validator for clarity
-			accountConstructor.genesisPrivateValidator = genesisPrivateValidator			
+			accountConstructor.genesisPrivateValidator = genesisPrivateValidator
 		}
 	}
 
@@ -187,6 +268,7 @@ func generateAddressAndKey(keyAddressType string, blockPrivateValidator bool) (a
 	if !blockPrivateValidator {
 		// TODO: [ben] check that empty byte slice returns error and does not unmarshal into
 		// zero GenesisPrivateValidator type
+		genesisPrivateValidator = &genesis.GenesisPrivateValidator{}
 		if err = json.Unmarshal(privateValidatorJson, genesisPrivateValidator); err != nil {
 			log.Error(string(privateValidatorJson))
 			return
@@ -0,0 +1,148 @@
+package maker
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/eris-ltd/eris-db/genesis"
+)
+
+// ConsensusParams mirrors the subset of Tendermint's consensus parameters
+// that a generated genesis.json needs to pin down so that every node on
+// a chain agrees on them from block zero.
+type ConsensusParams struct {
+	MaxBlockSizeBytes    int      `json:"max_block_size_bytes"`
+	MaxBlockSizeTxs      int      `json:"max_block_size_txs"`
+	MaxEvidenceAge       int64    `json:"max_evidence_age"` // nanoseconds, to keep this package free of tendermint's own duration type
+	ValidatorPubKeyTypes []string `json:"validator_pub_key_types"`
+}
+
+// DefaultConsensusParams returns the consensus parameters `eris chains
+// make` has always assumed implicitly, with ValidatorPubKeyTypes left
+// empty; GenerateGenesisFileBytes fills it in from the key schemes the
+// constructors' validators were actually built with.
+func DefaultConsensusParams() *ConsensusParams {
+	return &ConsensusParams{
+		MaxBlockSizeBytes: 22020096, // 21MB, tendermint's own default
+		MaxBlockSizeTxs:   10000,
+		MaxEvidenceAge:    int64(7 * 24 * time.Hour),
+	}
+}
+
+// validatorPubKeyTypes returns the distinct GenesisValidatorPubKeyType of
+// every constructor that produced a validator, in first-seen order. A
+// genesis document that mixes key schemes across its validators (e.g.
+// ed25519 and secp256k1,keccak256) must declare all of them here or
+// eris-db will reject the non-default validators' public keys.
+func validatorPubKeyTypes(constructors []*ErisDBAccountConstructor) []string {
+	seen := map[string]bool{}
+	var pubKeyTypes []string
+	for _, constructor := range constructors {
+		if constructor.genesisValidator == nil || constructor.validatorPubKeyType == "" {
+			continue
+		}
+		if !seen[constructor.validatorPubKeyType] {
+			seen[constructor.validatorPubKeyType] = true
+			pubKeyTypes = append(pubKeyTypes, constructor.validatorPubKeyType)
+		}
+	}
+	if len(pubKeyTypes) == 0 {
+		// fall back to the historical assumption for constructors built
+		// before validatorPubKeyType was recorded
+		pubKeyTypes = []string{"ed25519"}
+	}
+	return pubKeyTypes
+}
+
+// GenesisOptions holds the pieces of a genesis.json that aren't derived
+// from the account constructors themselves. Every field is optional;
+// GenerateGenesisFileBytes fills in sensible defaults for anything left
+// zero-valued.
+type GenesisOptions struct {
+	ChainID         string
+	GenesisTime     time.Time
+	ConsensusParams *ConsensusParams
+	// AppState is passed through verbatim into the "app_state" section of
+	// the genesis document, for chains that need to seed application
+	// (as opposed to account) state at genesis.
+	AppState json.RawMessage
+}
+
+// GenesisFile is the fully-formed genesis document GenerateGenesisFileBytes
+// produces and LoadGenesis parses back: chain_id, genesis_time, consensus
+// params, the accounts and validators from a set of ErisDBAccountConstructors,
+// an app_hash placeholder, and a pluggable app_state section.
+type GenesisFile struct {
+	GenesisTime     time.Time                   `json:"genesis_time"`
+	ChainID         string                      `json:"chain_id"`
+	ConsensusParams *ConsensusParams            `json:"consensus_params"`
+	Accounts        []*genesis.GenesisAccount   `json:"accounts"`
+	Validators      []*genesis.GenesisValidator `json:"validators"`
+	AppHash         []byte                      `json:"app_hash"`
+	AppState        json.RawMessage             `json:"app_state,omitempty"`
+}
+
+// GenerateGenesisFileBytes assembles a complete genesis.json for chainName
+// from constructors, centralizing what callers previously had to stitch
+// together themselves from GenesisAccount/GenesisValidator fragments. The
+// app_hash is left as the zero hash: it is computed by eris-db from
+// app_state once the chain starts, not by the maker.
+func GenerateGenesisFileBytes(chainName string, constructors []*ErisDBAccountConstructor, opts GenesisOptions) ([]byte, error) {
+	chainID := opts.ChainID
+	if chainID == "" {
+		chainID = chainName
+	}
+
+	genesisTime := opts.GenesisTime
+	if genesisTime.IsZero() {
+		genesisTime = time.Now()
+	}
+
+	consensusParams := opts.ConsensusParams
+	if consensusParams == nil {
+		consensusParams = DefaultConsensusParams()
+	}
+	if len(consensusParams.ValidatorPubKeyTypes) == 0 {
+		consensusParams.ValidatorPubKeyTypes = validatorPubKeyTypes(constructors)
+	}
+
+	file := &GenesisFile{
+		GenesisTime:     genesisTime,
+		ChainID:         chainID,
+		ConsensusParams: consensusParams,
+		AppHash:         make([]byte, 20),
+		AppState:        opts.AppState,
+	}
+
+	for _, constructor := range constructors {
+		if constructor.genesisAccount != nil {
+			file.Accounts = append(file.Accounts, constructor.genesisAccount)
+		}
+		if constructor.genesisValidator != nil {
+			file.Validators = append(file.Validators, constructor.genesisValidator)
+		}
+	}
+
+	if len(file.Validators) == 0 {
+		return nil, fmt.Errorf("Cannot generate genesis for %s: no account constructor produced a validator", chainName)
+	}
+
+	genesisBytes, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to marshal genesis.json for %s: %v", chainName, err)
+	}
+
+	return genesisBytes, nil
+}
+
+// LoadGenesis parses a genesis.json previously produced by
+// GenerateGenesisFileBytes, so that tests and tooling can round-trip a
+// single canonical artifact instead of re-deriving it from fragments.
+func LoadGenesis(genesisBytes []byte) (*GenesisFile, error) {
+	file := &GenesisFile{}
+	if err := json.Unmarshal(genesisBytes, file); err != nil {
+		return nil, fmt.Errorf("Failed to parse genesis.json: %v", err)
+	}
+	return file, nil
+}
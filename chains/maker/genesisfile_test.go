@@ -0,0 +1,56 @@
+package maker
+
+import (
+	"testing"
+
+	"github.com/eris-ltd/eris-db/genesis"
+	ptypes "github.com/eris-ltd/eris-db/permission/types"
+)
+
+func TestGenerateGenesisFileBytesRoundTrip(t *testing.T) {
+	permissions := &ptypes.AccountPermissions{}
+
+	validatorAccount, err := genesis.NewGenesisAccount([]byte{0x01}, 1000000, "testchain_validator_000", permissions)
+	if err != nil {
+		t.Fatalf("NewGenesisAccount: %v", err)
+	}
+	validator, err := genesis.NewGenesisValidator(1000000, "testchain_validator_000", []byte{0x01}, 1000000,
+		"secp256k1", []byte{0x02, 0x03})
+	if err != nil {
+		t.Fatalf("NewGenesisValidator: %v", err)
+	}
+
+	constructors := []*ErisDBAccountConstructor{
+		{
+			genesisAccount:      validatorAccount,
+			genesisValidator:    validator,
+			validatorPubKeyType: "secp256k1",
+		},
+	}
+
+	genesisBytes, err := GenerateGenesisFileBytes("testchain", constructors, GenesisOptions{})
+	if err != nil {
+		t.Fatalf("GenerateGenesisFileBytes: %v", err)
+	}
+
+	loaded, err := LoadGenesis(genesisBytes)
+	if err != nil {
+		t.Fatalf("LoadGenesis: %v", err)
+	}
+
+	if loaded.ChainID != "testchain" {
+		t.Errorf("ChainID = %q, want %q", loaded.ChainID, "testchain")
+	}
+	if len(loaded.Validators) != 1 {
+		t.Fatalf("len(Validators) = %d, want 1", len(loaded.Validators))
+	}
+	if len(loaded.ConsensusParams.ValidatorPubKeyTypes) != 1 || loaded.ConsensusParams.ValidatorPubKeyTypes[0] != "secp256k1" {
+		t.Errorf("ValidatorPubKeyTypes = %v, want [secp256k1]", loaded.ConsensusParams.ValidatorPubKeyTypes)
+	}
+}
+
+func TestGenerateGenesisFileBytesRequiresValidator(t *testing.T) {
+	if _, err := GenerateGenesisFileBytes("testchain", nil, GenesisOptions{}); err == nil {
+		t.Fatal("expected an error when no constructor produces a validator")
+	}
+}
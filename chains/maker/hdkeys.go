@@ -0,0 +1,124 @@
+package maker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/tyler-smith/go-bip32"
+	"github.com/tyler-smith/go-bip39"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// DefaultHDCoinType is the BIP44 coin type used to derive accounts from a
+// mnemonic when the caller does not specify one; 60 is Ethereum's
+// registered coin type and is as reasonable a default as any for chains
+// that don't register their own.
+const DefaultHDCoinType = 60
+
+// NewMnemonic generates a fresh 24-word BIP39 mnemonic for MakeAccounts
+// to derive keys from. No `eris chains make` flag calls it.
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(256)
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate entropy for mnemonic: %v", err)
+	}
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return "", fmt.Errorf("Failed to generate mnemonic: %v", err)
+	}
+	return mnemonic, nil
+}
+
+// hdDerivationPath returns the BIP32/BIP44 path an account should be
+// derived at: m/44'/coinType'/accountTypeIndex'/0/i. Recording this path
+// on the ErisDBAccountConstructor alongside the key it produced is what
+// lets a chain be regenerated byte-for-byte from the mnemonic alone.
+func hdDerivationPath(coinType, accountTypeIndex, i int) string {
+	return fmt.Sprintf("m/44'/%d'/%d'/0/%d", coinType, accountTypeIndex, i)
+}
+
+// deriveKeyPair deterministically derives a public key and address for
+// keyAddressType from mnemonic at the BIP32 path, instead of asking
+// eris-keys or a SignerBackend to generate a random one. Because the key
+// is reproducible from the mnemonic and path alone, callers in
+// mnemonic mode never need to extract or persist a private key.
+//
+// Only "secp256k1,keccak256" is supported: BIP32 is an EC (secp256k1)
+// child-key derivation scheme, and there is no sound way to turn its
+// output into an ed25519 seed by feeding it through NewKeyFromSeed, as an
+// earlier version of this function did. ed25519 HD derivation has its
+// own standard (SLIP-0010) that this package does not yet implement;
+// until it does, mnemonic mode is refused for "ed25519,ripemd160" rather
+// than silently producing keys that aren't interoperable with any other
+// BIP32 or SLIP-0010 wallet.
+func deriveKeyPair(mnemonic, path, keyAddressType string) (address, publicKey []byte, err error) {
+	if keyAddressType != "secp256k1,keccak256" {
+		return nil, nil, fmt.Errorf(
+			"Mnemonic derivation only supports the secp256k1,keccak256 key scheme (got %q); "+
+				"ed25519 HD derivation needs SLIP-0010, which this package does not implement", keyAddressType)
+	}
+
+	seed := bip39.NewSeed(mnemonic, "")
+
+	master, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to derive master key from mnemonic: %v", err)
+	}
+
+	child, err := deriveBIP32Path(master, path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to derive %s: %v", path, err)
+	}
+
+	_, pubKey := btcec.PrivKeyFromBytes(btcec.S256(), child.Key)
+	publicKey = pubKey.SerializeUncompressed()
+	if address, err = secp256k1KeccakAddress(publicKey); err != nil {
+		return nil, nil, err
+	}
+
+	return address, publicKey, nil
+}
+
+// deriveBIP32Path walks a BIP32 path of the form m/44'/60'/0'/0/0 from
+// master, hardening each segment that is suffixed with a single quote.
+func deriveBIP32Path(master *bip32.Key, path string) (*bip32.Key, error) {
+	segments, err := parseBIP32Path(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key := master
+	for _, segment := range segments {
+		if key, err = key.NewChildKey(segment); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// parseBIP32Path parses a path of the form m/44'/60'/0'/0/0 into the
+// sequence of BIP32 child indices it represents, hardening each segment
+// suffixed with a single quote.
+func parseBIP32Path(path string) ([]uint32, error) {
+	parts := strings.Split(path, "/")
+	if len(parts) == 0 || parts[0] != "m" {
+		return nil, fmt.Errorf("Derivation path %q must start with \"m/\"", path)
+	}
+
+	indices := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		hardened := strings.HasSuffix(part, "'")
+		index, err := strconv.ParseUint(strings.TrimSuffix(part, "'"), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid derivation path segment %q: %v", part, err)
+		}
+		if hardened {
+			index += bip32.FirstHardenedChild
+		}
+		indices = append(indices, uint32(index))
+	}
+
+	return indices, nil
+}
@@ -0,0 +1,53 @@
+package maker
+
+import (
+	"bytes"
+	"testing"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestDeriveKeyPairIsDeterministic(t *testing.T) {
+	path := hdDerivationPath(DefaultHDCoinType, 0, 0)
+
+	address1, publicKey1, err := deriveKeyPair(testMnemonic, path, "secp256k1,keccak256")
+	if err != nil {
+		t.Fatalf("deriveKeyPair: %v", err)
+	}
+	address2, publicKey2, err := deriveKeyPair(testMnemonic, path, "secp256k1,keccak256")
+	if err != nil {
+		t.Fatalf("deriveKeyPair: %v", err)
+	}
+
+	if !bytes.Equal(address1, address2) {
+		t.Errorf("address changed between runs: %x != %x", address1, address2)
+	}
+	if !bytes.Equal(publicKey1, publicKey2) {
+		t.Errorf("public key changed between runs: %x != %x", publicKey1, publicKey2)
+	}
+	if len(address1) != 20 {
+		t.Errorf("len(address) = %d, want 20", len(address1))
+	}
+}
+
+func TestDeriveKeyPairDiffersByPath(t *testing.T) {
+	addressA, _, err := deriveKeyPair(testMnemonic, hdDerivationPath(DefaultHDCoinType, 0, 0), "secp256k1,keccak256")
+	if err != nil {
+		t.Fatalf("deriveKeyPair: %v", err)
+	}
+	addressB, _, err := deriveKeyPair(testMnemonic, hdDerivationPath(DefaultHDCoinType, 0, 1), "secp256k1,keccak256")
+	if err != nil {
+		t.Fatalf("deriveKeyPair: %v", err)
+	}
+
+	if bytes.Equal(addressA, addressB) {
+		t.Errorf("expected different accounts at different paths to derive different addresses")
+	}
+}
+
+func TestDeriveKeyPairRejectsEd25519(t *testing.T) {
+	path := hdDerivationPath(DefaultHDCoinType, 0, 0)
+	if _, _, err := deriveKeyPair(testMnemonic, path, "ed25519,ripemd160"); err == nil {
+		t.Fatal("expected an error deriving ed25519,ripemd160 from a mnemonic")
+	}
+}
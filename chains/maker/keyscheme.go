@@ -0,0 +1,79 @@
+package maker
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/sha3"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// KeyScheme describes a PKI/address pair that accounts can be generated
+// with: the tendermint/go-crypto pub-key type byte that must be written
+// alongside the public key, the string eris-db genesis expects for a
+// GenesisValidator's public key type, and how to turn a PKI public key
+// into the address that accounts/validators are identified by.
+//
+// newErisDBAccountConstructor looks a KeyScheme up by the same
+// "<pki>,<address-hash>" string that keyAddressType has always been, so
+// existing chains (keyed on "ed25519,ripemd160") keep working unchanged.
+type KeyScheme struct {
+	// TypeBytePublicKey is the tendermint/go-crypto typebyte written into
+	// the untyped public key fields of the account constructor.
+	TypeBytePublicKey byte
+	// GenesisValidatorPubKeyType is the string genesis.NewGenesisValidator
+	// expects for this scheme's public key type.
+	GenesisValidatorPubKeyType string
+	// AddressFromPublicKey derives an account address from a raw public
+	// key as returned by the signer backend.
+	AddressFromPublicKey func(publicKey []byte) ([]byte, error)
+}
+
+// keySchemes is the registry of supported "<pki>,<address-hash>" schemes,
+// keyed exactly as keyAddressType is passed through MakeAccounts.
+var keySchemes = map[string]KeyScheme{
+	"ed25519,ripemd160": {
+		TypeBytePublicKey:          0x01,
+		GenesisValidatorPubKeyType: "ed25519",
+		// the ripemd160 address is computed server-side by eris-keys, so
+		// there is nothing for maker to derive locally.
+		AddressFromPublicKey: nil,
+	},
+	// secp256k1,keccak256 produces Ethereum-compatible accounts: the
+	// public key is an uncompressed secp256k1 point and the address is
+	// the last 20 bytes of its Keccak256 hash, exactly as go-ethereum
+	// derives addresses.
+	"secp256k1,keccak256": {
+		TypeBytePublicKey:          0x02,
+		GenesisValidatorPubKeyType: "secp256k1",
+		AddressFromPublicKey:       secp256k1KeccakAddress,
+	},
+}
+
+// keySchemeFor looks up the KeyScheme registered for keyAddressType.
+func keySchemeFor(keyAddressType string) (KeyScheme, error) {
+	scheme, ok := keySchemes[keyAddressType]
+	if !ok {
+		return KeyScheme{}, fmt.Errorf("Unknown key scheme (keyAddressType: %s)", keyAddressType)
+	}
+	return scheme, nil
+}
+
+// secp256k1KeccakAddress derives an Ethereum-style 20-byte address from
+// an uncompressed secp256k1 public key: Keccak256(pubKey)[12:32].
+func secp256k1KeccakAddress(publicKey []byte) ([]byte, error) {
+	pubKey, err := btcec.ParsePubKey(publicKey, btcec.S256())
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse secp256k1 public key: %v", err)
+	}
+
+	// hash the 64-byte uncompressed coordinates, without the 0x04 prefix,
+	// as go-ethereum does for Ethereum address derivation
+	uncompressed := pubKey.SerializeUncompressed()[1:]
+
+	hash := sha3.NewLegacyKeccak256()
+	hash.Write(uncompressed)
+	digest := hash.Sum(nil)
+
+	return digest[12:], nil
+}
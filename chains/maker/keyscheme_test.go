@@ -0,0 +1,24 @@
+package maker
+
+import "testing"
+
+func TestKeySchemeForKnownSchemes(t *testing.T) {
+	for keyAddressType, want := range map[string]byte{
+		"ed25519,ripemd160":   0x01,
+		"secp256k1,keccak256": 0x02,
+	} {
+		scheme, err := keySchemeFor(keyAddressType)
+		if err != nil {
+			t.Fatalf("keySchemeFor(%q): %v", keyAddressType, err)
+		}
+		if scheme.TypeBytePublicKey != want {
+			t.Errorf("keySchemeFor(%q).TypeBytePublicKey = %#x, want %#x", keyAddressType, scheme.TypeBytePublicKey, want)
+		}
+	}
+}
+
+func TestKeySchemeForUnknownScheme(t *testing.T) {
+	if _, err := keySchemeFor("rot13,crc32"); err == nil {
+		t.Fatal("expected an error for an unregistered key scheme")
+	}
+}
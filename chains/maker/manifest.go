@@ -0,0 +1,190 @@
+package maker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+
+	"github.com/eris-ltd/eris/definitions"
+)
+
+// accountTypePresets holds the reusable named bundles of defaults a manifest
+// entry can start from with `preset: <name>` before overriding individual
+// fields. Presets mirror the account types operators have always hand
+// rolled on the command line for `eris chains make`.
+var accountTypePresets = map[string]AccountType{
+	// Full holds all permissions and a large token balance; suitable for
+	// a single all-powerful account on a development chain.
+	"Full": {
+		ErisDBAccountType: &definitions.ErisDBAccountType{
+			Number: 1,
+			Tokens: 9999999999,
+			Perms:  map[string]int{"root": 1, "send": 1, "call": 1, "create_contract": 1, "create_account": 1, "bond": 1, "name": 1, "has_base": 1, "set_base": 1, "unset_base": 1, "set_global": 1, "has_role": 1, "add_role": 1, "rm_role": 1},
+		},
+	},
+	// Validator bonds its full token balance so it is picked up as a
+	// GenesisValidator, with permissions limited to bonding, sending and
+	// registering names.
+	"Validator": {
+		ErisDBAccountType: &definitions.ErisDBAccountType{
+			Number: 1,
+			Tokens: 1000000,
+			ToBond: 1000000,
+			Perms:  map[string]int{"send": 1, "bond": 1, "name": 1},
+		},
+		Roles: []string{"validator"},
+	},
+	// Developer can deploy and call contracts and create accounts, but
+	// cannot bond as a validator or change chain-wide permissions.
+	"Developer": {
+		ErisDBAccountType: &definitions.ErisDBAccountType{
+			Number: 1,
+			Tokens: 1000000,
+			Perms:  map[string]int{"send": 1, "call": 1, "create_contract": 1, "create_account": 1, "name": 1},
+		},
+		Roles: []string{"developer"},
+	},
+	// Participant can only send tokens and call existing contracts; the
+	// preset for ordinary users of a chain.
+	"Participant": {
+		ErisDBAccountType: &definitions.ErisDBAccountType{
+			Number: 1,
+			Tokens: 10000,
+			Perms:  map[string]int{"send": 1, "call": 1},
+		},
+		Roles: []string{"participant"},
+	},
+	// Root has the `root` permission and the has_base, set_base,
+	// unset_base and set_global permissions, and is intended for chain
+	// governance accounts.
+	"Root": {
+		ErisDBAccountType: &definitions.ErisDBAccountType{
+			Number: 1,
+			Tokens: 9999999999,
+			Perms:  map[string]int{"root": 1, "set_base": 1, "set_global": 1, "has_base": 1, "unset_base": 1},
+		},
+		Roles: []string{"root"},
+	},
+}
+
+// manifestFile is the on-disk shape of a chain-maker manifest, decoded
+// from either YAML or TOML depending on the file's extension.
+type manifestFile struct {
+	Accounts []manifestAccountType `yaml:"accounts" toml:"accounts"`
+}
+
+// manifestAccountType is a single named entry in a manifest. Preset, when
+// set, is applied before the rest of the fields override it, so a
+// manifest only needs to state what differs from the preset.
+type manifestAccountType struct {
+	Name          string         `yaml:"name" toml:"name"`
+	Preset        string         `yaml:"preset" toml:"preset"`
+	Number        int            `yaml:"number" toml:"number"`
+	Tokens        int            `yaml:"tokens" toml:"tokens"`
+	ToBond        int            `yaml:"to_bond" toml:"to_bond"`
+	Perms         map[string]int `yaml:"perms" toml:"perms"`
+	Roles         []string       `yaml:"roles" toml:"roles"`
+	KeyScheme     string         `yaml:"key_scheme" toml:"key_scheme"`
+	SignerBackend string         `yaml:"signer_backend" toml:"signer_backend"`
+	SignerAddress string         `yaml:"signer_address" toml:"signer_address"`
+}
+
+// LoadAccountTypesFromFile reads a chain-maker manifest from path and
+// returns the []*AccountType it declares, ready to pass to MakeAccounts.
+// The format (YAML or TOML) is chosen from the file extension (.yaml/.yml
+// or .toml). A manifest lets chain topology be checked into version
+// control instead of assembled from CLI flags; no `eris chains make` flag
+// calls this function.
+func LoadAccountTypesFromFile(path string) ([]*AccountType, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read manifest %s: %v", path, err)
+	}
+
+	var manifest manifestFile
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err = yaml.Unmarshal(raw, &manifest); err != nil {
+			return nil, fmt.Errorf("Failed to parse YAML manifest %s: %v", path, err)
+		}
+	case ".toml":
+		if err = toml.Unmarshal(raw, &manifest); err != nil {
+			return nil, fmt.Errorf("Failed to parse TOML manifest %s: %v", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("Unrecognised manifest extension %q (expected .yaml, .yml or .toml)", ext)
+	}
+
+	accountTypes := make([]*AccountType, 0, len(manifest.Accounts))
+	for _, entry := range manifest.Accounts {
+		accountType, err := resolveManifestAccountType(entry)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to resolve account type %q in %s: %v", entry.Name, path, err)
+		}
+		accountTypes = append(accountTypes, accountType)
+	}
+
+	return accountTypes, nil
+}
+
+// resolveManifestAccountType applies entry's preset, if any, and then
+// lets entry's own fields override it field by field.
+func resolveManifestAccountType(entry manifestAccountType) (*AccountType, error) {
+	accountType := AccountType{ErisDBAccountType: &definitions.ErisDBAccountType{}}
+
+	if entry.Preset != "" {
+		preset, ok := accountTypePresets[entry.Preset]
+		if !ok {
+			return nil, fmt.Errorf("Unknown account type preset %q", entry.Preset)
+		}
+		// copy the preset rather than aliasing it, so overriding a field
+		// below can never mutate the shared accountTypePresets entry.
+		presetAccountType := *preset.ErisDBAccountType
+		accountType = AccountType{
+			ErisDBAccountType: &presetAccountType,
+			Roles:             preset.Roles,
+			KeyScheme:         preset.KeyScheme,
+			SignerBackend:     preset.SignerBackend,
+			SignerAddress:     preset.SignerAddress,
+		}
+	}
+
+	accountType.Name = entry.Name
+	if entry.Number != 0 {
+		accountType.Number = entry.Number
+	}
+	if entry.Tokens != 0 {
+		accountType.Tokens = entry.Tokens
+	}
+	if entry.ToBond != 0 {
+		accountType.ToBond = entry.ToBond
+	}
+	if entry.Perms != nil {
+		accountType.Perms = entry.Perms
+	}
+	if entry.Roles != nil {
+		accountType.Roles = entry.Roles
+	}
+	if entry.KeyScheme != "" {
+		accountType.KeyScheme = entry.KeyScheme
+	}
+	if entry.SignerBackend != "" {
+		accountType.SignerBackend = entry.SignerBackend
+	}
+	if entry.SignerAddress != "" {
+		accountType.SignerAddress = entry.SignerAddress
+	}
+
+	if accountType.Name == "" {
+		return nil, fmt.Errorf("Manifest account type entry is missing a name")
+	}
+	if accountType.Number <= 0 {
+		return nil, fmt.Errorf("Account type %q must have number > 0", accountType.Name)
+	}
+
+	return &accountType, nil
+}
@@ -0,0 +1,115 @@
+package maker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveManifestAccountTypeAppliesPreset(t *testing.T) {
+	accountType, err := resolveManifestAccountType(manifestAccountType{
+		Name:   "validator_000",
+		Preset: "Validator",
+	})
+	if err != nil {
+		t.Fatalf("resolveManifestAccountType: %v", err)
+	}
+	if accountType.ToBond != 1000000 || accountType.Tokens != 1000000 {
+		t.Errorf("Tokens/ToBond = %d/%d, want the Validator preset's 1000000/1000000", accountType.Tokens, accountType.ToBond)
+	}
+	if len(accountType.Roles) != 1 || accountType.Roles[0] != "validator" {
+		t.Errorf("Roles = %v, want [validator] from the preset", accountType.Roles)
+	}
+}
+
+func TestResolveManifestAccountTypeOverridesPreset(t *testing.T) {
+	accountType, err := resolveManifestAccountType(manifestAccountType{
+		Name:   "validator_000",
+		Preset: "Validator",
+		Tokens: 42,
+	})
+	if err != nil {
+		t.Fatalf("resolveManifestAccountType: %v", err)
+	}
+	if accountType.Tokens != 42 {
+		t.Errorf("Tokens = %d, want 42 (entry override)", accountType.Tokens)
+	}
+	// ToBond is left at the preset's value since the entry didn't override it.
+	if accountType.ToBond != 1000000 {
+		t.Errorf("ToBond = %d, want the Validator preset's 1000000", accountType.ToBond)
+	}
+
+	// applying and overriding the preset must not have mutated the shared
+	// accountTypePresets entry for later callers.
+	preset := accountTypePresets["Validator"]
+	if preset.Tokens != 1000000 {
+		t.Errorf("accountTypePresets[\"Validator\"].Tokens = %d, want unchanged 1000000", preset.Tokens)
+	}
+}
+
+func TestResolveManifestAccountTypeUnknownPreset(t *testing.T) {
+	if _, err := resolveManifestAccountType(manifestAccountType{Name: "x", Preset: "Nonexistent"}); err == nil {
+		t.Fatal("expected an error for an unknown preset")
+	}
+}
+
+func TestResolveManifestAccountTypeMissingName(t *testing.T) {
+	if _, err := resolveManifestAccountType(manifestAccountType{Number: 1}); err == nil {
+		t.Fatal("expected an error for a manifest entry with no name")
+	}
+}
+
+func TestResolveManifestAccountTypeRequiresPositiveNumber(t *testing.T) {
+	if _, err := resolveManifestAccountType(manifestAccountType{Name: "x", Number: 0}); err == nil {
+		t.Fatal("expected an error when number <= 0")
+	}
+	if _, err := resolveManifestAccountType(manifestAccountType{Name: "x", Number: -1}); err == nil {
+		t.Fatal("expected an error when number <= 0")
+	}
+}
+
+func TestLoadAccountTypesFromFileRejectsUnrecognisedExtension(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "chain.json")
+	if err = ioutil.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if _, err = LoadAccountTypesFromFile(path); err == nil {
+		t.Fatal("expected an error for an unrecognised manifest extension")
+	}
+}
+
+func TestLoadAccountTypesFromFileYAML(t *testing.T) {
+	dir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "chain.yaml")
+	manifest := "accounts:\n  - name: validator_000\n    preset: Validator\n    tokens: 42\n"
+	if err = ioutil.WriteFile(path, []byte(manifest), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	accountTypes, err := LoadAccountTypesFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadAccountTypesFromFile: %v", err)
+	}
+	if len(accountTypes) != 1 {
+		t.Fatalf("len(accountTypes) = %d, want 1", len(accountTypes))
+	}
+	if accountTypes[0].Tokens != 42 {
+		t.Errorf("Tokens = %d, want 42 (entry override)", accountTypes[0].Tokens)
+	}
+	if accountTypes[0].ToBond != 1000000 {
+		t.Errorf("ToBond = %d, want the Validator preset's 1000000", accountTypes[0].ToBond)
+	}
+}
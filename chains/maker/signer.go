@@ -0,0 +1,190 @@
+package maker
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/eris-ltd/eris/log"
+
+	"github.com/eris-ltd/eris-db/genesis"
+)
+
+// dialTimeout bounds how long remoteSignerSocket and httpKMSSigner will
+// wait to reach a remote signer or KMS before giving up, so that a signer
+// that never answers cannot hang `eris chains make` forever.
+const dialTimeout = 10 * time.Second
+
+// SignerBackend abstracts how the public key (and, only for local
+// development signing, the private key material) of a validator identity
+// is obtained. This lets newErisDBAccountConstructor provision a validator
+// against eris-keys, a Tendermint remote-signer socket, or an HTTP KMS
+// without caring which one it is talking to: in every case but eris-keys
+// the private key never leaves the signer and only an address and public
+// key are returned.
+type SignerBackend interface {
+	// GenerateAddressAndKey returns the address and public key of a
+	// validator identity. Only a backend that holds the key locally
+	// (eris-keys) is able to return a non-nil genesisPrivateValidator,
+	// and even then only when blockPrivateValidator is false.
+	GenerateAddressAndKey(keyAddressType string, blockPrivateValidator bool) (address, publicKey []byte, genesisPrivateValidator *genesis.GenesisPrivateValidator, err error)
+}
+
+// Recognised values for AccountType.SignerBackend. The empty string is
+// equivalent to SignerBackendErisKeys, preserving existing behaviour for
+// manifests that don't specify a signer.
+const (
+	SignerBackendErisKeys   = "eris-keys"
+	SignerBackendTendermint = "tendermint-socket"
+	SignerBackendHTTPKMS    = "kms-http"
+)
+
+// localKeysSigner is the pre-existing eris-keys backed signer. It is the
+// only backend that is able to extract a GenesisPrivateValidator, and it
+// only does so when blockPrivateValidator is false.
+type localKeysSigner struct{}
+
+func (localKeysSigner) GenerateAddressAndKey(keyAddressType string, blockPrivateValidator bool) ([]byte, []byte, *genesis.GenesisPrivateValidator, error) {
+	return generateAddressAndKey(keyAddressType, blockPrivateValidator)
+}
+
+// remoteSignerSocket provisions a validator against a Tendermint
+// remote-signer process listening on SocketAddress (tcp:// or unix://).
+// The private key is generated and kept by the remote signer itself;
+// this backend only reads back the address and public key it will sign
+// with, so `eris chains make` never has the private key to lose.
+type remoteSignerSocket struct {
+	SocketAddress string
+}
+
+// NewRemoteSignerSocket returns a SignerBackend that asks an already
+// running Tendermint remote-signer for its address and public key over
+// socketAddress, instead of extracting a private key from eris-keys.
+func NewRemoteSignerSocket(socketAddress string) SignerBackend {
+	return &remoteSignerSocket{SocketAddress: socketAddress}
+}
+
+func (r *remoteSignerSocket) GenerateAddressAndKey(keyAddressType string, blockPrivateValidator bool) (address, publicKey []byte, genesisPrivateValidator *genesis.GenesisPrivateValidator, err error) {
+	log.WithFields(log.Fields{
+		"socket": r.SocketAddress,
+		"type":   keyAddressType,
+	}).Debug("Requesting validator public key from remote signer")
+
+	network, socketAddress := "tcp", strings.TrimPrefix(r.SocketAddress, "tcp://")
+	if strings.HasPrefix(r.SocketAddress, "unix://") {
+		network, socketAddress = "unix", strings.TrimPrefix(r.SocketAddress, "unix://")
+	}
+	conn, err := net.DialTimeout(network, socketAddress, dialTimeout)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("Failed to dial remote signer at %s: %v", r.SocketAddress, err)
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(dialTimeout)); err != nil {
+		return nil, nil, nil, fmt.Errorf("Failed to set deadline for remote signer at %s: %v", r.SocketAddress, err)
+	}
+
+	request, err := json.Marshal(map[string]string{"key_type": keyAddressType})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if _, err = conn.Write(append(request, '\n')); err != nil {
+		return nil, nil, nil, fmt.Errorf("Failed to write request to remote signer: %v", err)
+	}
+
+	var response struct {
+		Address   string `json:"address"`
+		PublicKey string `json:"pub_key"`
+	}
+	if err = json.NewDecoder(conn).Decode(&response); err != nil {
+		return nil, nil, nil, fmt.Errorf("Failed to read response from remote signer: %v", err)
+	}
+
+	if address, err = hex.DecodeString(response.Address); err != nil {
+		return nil, nil, nil, err
+	}
+	if publicKey, err = hex.DecodeString(response.PublicKey); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// a remote signer never gives up its private key
+	return address, publicKey, nil, nil
+}
+
+// httpKMSSigner provisions a validator against an HTTP KMS endpoint that
+// already holds (or creates, on first request) a validator signing key of
+// the requested key type. As with remoteSignerSocket, only the public
+// half of the key ever reaches this process.
+type httpKMSSigner struct {
+	Endpoint string
+}
+
+// httpKMSClient is shared by every httpKMSSigner; a KMS that never
+// responds should fail a single `eris chains make` call, not hang it.
+var httpKMSClient = &http.Client{Timeout: dialTimeout}
+
+// NewHTTPKMSSigner returns a SignerBackend that asks an HTTP KMS at
+// endpoint for the address and public key of a validator identity.
+func NewHTTPKMSSigner(endpoint string) SignerBackend {
+	return &httpKMSSigner{Endpoint: endpoint}
+}
+
+func (k *httpKMSSigner) GenerateAddressAndKey(keyAddressType string, blockPrivateValidator bool) (address, publicKey []byte, genesisPrivateValidator *genesis.GenesisPrivateValidator, err error) {
+	log.WithFields(log.Fields{
+		"endpoint": k.Endpoint,
+		"type":     keyAddressType,
+	}).Debug("Requesting validator public key from HTTP KMS")
+
+	resp, err := httpKMSClient.Get(fmt.Sprintf("%s/v1/keys?type=%s", k.Endpoint, keyAddressType))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("Failed to reach KMS at %s: %v", k.Endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, nil, fmt.Errorf("KMS at %s returned status %s", k.Endpoint, resp.Status)
+	}
+
+	var response struct {
+		Address   string `json:"address"`
+		PublicKey string `json:"pub_key"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, nil, nil, fmt.Errorf("Failed to decode response from KMS: %v", err)
+	}
+
+	if address, err = hex.DecodeString(response.Address); err != nil {
+		return nil, nil, nil, err
+	}
+	if publicKey, err = hex.DecodeString(response.PublicKey); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// the KMS keeps the private key; we only ever learn the public half
+	return address, publicKey, nil, nil
+}
+
+// signerBackendFor selects the SignerBackend to use for accountType,
+// defaulting to the local eris-keys backend so that chains which don't
+// declare a signer backend keep their current (development) behaviour.
+func signerBackendFor(accountType *AccountType) (SignerBackend, error) {
+	switch accountType.SignerBackend {
+	case "", SignerBackendErisKeys:
+		return localKeysSigner{}, nil
+	case SignerBackendTendermint:
+		if accountType.SignerAddress == "" {
+			return nil, fmt.Errorf("SignerBackend %q requires SignerAddress to be set", SignerBackendTendermint)
+		}
+		return NewRemoteSignerSocket(accountType.SignerAddress), nil
+	case SignerBackendHTTPKMS:
+		if accountType.SignerAddress == "" {
+			return nil, fmt.Errorf("SignerBackend %q requires SignerAddress to be set", SignerBackendHTTPKMS)
+		}
+		return NewHTTPKMSSigner(accountType.SignerAddress), nil
+	default:
+		return nil, fmt.Errorf("Unknown signer backend %q", accountType.SignerBackend)
+	}
+}
@@ -0,0 +1,47 @@
+package maker
+
+import "testing"
+
+func TestSignerBackendForDefaultsToErisKeys(t *testing.T) {
+	for _, signerBackend := range []string{"", SignerBackendErisKeys} {
+		backend, err := signerBackendFor(&AccountType{SignerBackend: signerBackend})
+		if err != nil {
+			t.Fatalf("signerBackendFor(%q): %v", signerBackend, err)
+		}
+		if _, ok := backend.(localKeysSigner); !ok {
+			t.Errorf("signerBackendFor(%q) = %T, want localKeysSigner", signerBackend, backend)
+		}
+	}
+}
+
+func TestSignerBackendForRequiresSignerAddress(t *testing.T) {
+	for _, signerBackend := range []string{SignerBackendTendermint, SignerBackendHTTPKMS} {
+		if _, err := signerBackendFor(&AccountType{SignerBackend: signerBackend}); err == nil {
+			t.Errorf("signerBackendFor(%q) with no SignerAddress: expected error, got nil", signerBackend)
+		}
+	}
+}
+
+func TestSignerBackendForRemoteBackends(t *testing.T) {
+	backend, err := signerBackendFor(&AccountType{SignerBackend: SignerBackendTendermint, SignerAddress: "127.0.0.1:46659"})
+	if err != nil {
+		t.Fatalf("signerBackendFor(%q): %v", SignerBackendTendermint, err)
+	}
+	if _, ok := backend.(*remoteSignerSocket); !ok {
+		t.Errorf("signerBackendFor(%q) = %T, want *remoteSignerSocket", SignerBackendTendermint, backend)
+	}
+
+	backend, err = signerBackendFor(&AccountType{SignerBackend: SignerBackendHTTPKMS, SignerAddress: "http://127.0.0.1:1234"})
+	if err != nil {
+		t.Fatalf("signerBackendFor(%q): %v", SignerBackendHTTPKMS, err)
+	}
+	if _, ok := backend.(*httpKMSSigner); !ok {
+		t.Errorf("signerBackendFor(%q) = %T, want *httpKMSSigner", SignerBackendHTTPKMS, backend)
+	}
+}
+
+func TestSignerBackendForUnknownBackend(t *testing.T) {
+	if _, err := signerBackendFor(&AccountType{SignerBackend: "carrier-pigeon"}); err == nil {
+		t.Fatal("expected an error for an unknown signer backend")
+	}
+}
@@ -0,0 +1,287 @@
+package maker
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/ripemd160"
+
+	"github.com/eris-ltd/eris/definitions"
+	"github.com/eris-ltd/eris/log"
+)
+
+// errDockerComposeNeedsColocatedLayout is returned by MakeTestnet when
+// both EmitDockerCompose and Hostnames (the split-host layout) are set.
+// writeDockerCompose only knows how to address colocated nodes by name
+// under OutputDir, so a split-host docker-compose.yml would reference
+// directories that were never written and bring nothing up.
+var errDockerComposeNeedsColocatedLayout = fmt.Errorf(
+	"EmitDockerCompose is only valid for the colocated layout; it cannot be combined with Hostnames")
+
+// TestnetOptions configures MakeTestnet. Every field is optional and
+// falls back to the single-machine development defaults `chains make`
+// has always used.
+type TestnetOptions struct {
+	// OutputDir is the root the node directories are written under: one
+	// subdirectory per node (e.g. "./testnet/node0") in the colocated
+	// layout, or one per hostname when Hostnames is set. Defaults to
+	// "./testnet".
+	OutputDir string
+	// Hostnames, when set, must have one entry per node and switches
+	// MakeTestnet to a split-host layout: one directory per hostname,
+	// and persistent_peers addressed by hostname instead of subnet IP.
+	Hostnames []string
+	// Subnet is the /24 base (e.g. "192.168.56") colocated nodes are
+	// assigned sequential IPs on, starting at .2. Defaults to "192.168.56".
+	Subnet string
+	// BaseP2PPort is the Tendermint p2p listen port every node binds.
+	// Defaults to 46656.
+	BaseP2PPort int
+	// SignerBackend and SignerAddress, when set, are applied to every
+	// node's validator account (see SignerBackend in signer.go) so that a
+	// testnet's validator keys can be provisioned from a remote-signer or
+	// KMS instead of local eris-keys.
+	SignerBackend string
+	SignerAddress string
+	// Unsafe, as elsewhere in this package, allows the validator's
+	// private key to be extracted and written to priv_validator.json.
+	// Only meaningful when SignerBackend is unset (eris-keys).
+	Unsafe bool
+	// EmitDockerCompose additionally writes a docker-compose.yml to
+	// OutputDir that brings up all nodes locally. Only valid for the
+	// colocated layout; MakeTestnet rejects it combined with Hostnames.
+	EmitDockerCompose bool
+}
+
+// TestnetNode describes one node of a testnet generated by MakeTestnet:
+// where its files were written and how its peers can reach it.
+type TestnetNode struct {
+	Index         int    `json:"index"`
+	Name          string `json:"name"`
+	Dir           string `json:"dir"`
+	ListenAddress string `json:"listen_address"`
+	NodeID        string `json:"node_id"`
+}
+
+// MakeTestnet lays out a multi-node devnet: nodes directories each
+// containing a node_key.json, a priv_validator.json for that node's own
+// validator (holding its private key when opts.Unsafe extracted one, a
+// remote-signer stub otherwise), and a genesis.json shared by every node
+// whose validator set is assembled from all of their validator keys -
+// analogous to a "collect-gentxs" step. accountTypes are generated once
+// and included in every node's genesis.json; an account type that bonds
+// tokens also adds its accounts to the validator set, with no node of
+// their own.
+func MakeTestnet(name string, nodes int, accountTypes []*AccountType, opts TestnetOptions) ([]*TestnetNode, error) {
+	if nodes <= 0 {
+		return nil, fmt.Errorf("MakeTestnet requires at least one node (got %d)", nodes)
+	}
+	if len(opts.Hostnames) != 0 && len(opts.Hostnames) != nodes {
+		return nil, fmt.Errorf("Got %d --hostnames but %d nodes", len(opts.Hostnames), nodes)
+	}
+	if opts.EmitDockerCompose && len(opts.Hostnames) != 0 {
+		return nil, errDockerComposeNeedsColocatedLayout
+	}
+
+	outputDir := opts.OutputDir
+	if outputDir == "" {
+		outputDir = "./testnet"
+	}
+	basePort := opts.BaseP2PPort
+	if basePort == 0 {
+		basePort = 46656
+	}
+	subnet := opts.Subnet
+	if subnet == "" {
+		subnet = "192.168.56"
+	}
+
+	// every node bonds its own validator account; these are collected
+	// below into the single genesis.json shared by the whole testnet.
+	validatorAccountType := &AccountType{
+		ErisDBAccountType: &definitions.ErisDBAccountType{
+			Name:   "validator",
+			Number: nodes,
+			Tokens: 1000000,
+			ToBond: 1000000,
+			Perms:  map[string]int{"send": 1, "bond": 1, "name": 1},
+		},
+		SignerBackend: opts.SignerBackend,
+		SignerAddress: opts.SignerAddress,
+	}
+	validatorConstructors, err := MakeAccounts(name, "mint", []*AccountType{validatorAccountType}, opts.Unsafe, "")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to generate per-node validator keys for testnet %s: %v", name, err)
+	}
+
+	sharedConstructors, err := MakeAccounts(name, "mint", accountTypes, opts.Unsafe, "")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to generate shared accounts for testnet %s: %v", name, err)
+	}
+
+	genesisBytes, err := GenerateGenesisFileBytes(name,
+		append(append([]*ErisDBAccountConstructor{}, validatorConstructors...), sharedConstructors...),
+		GenesisOptions{ChainID: name})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to assemble shared genesis.json for testnet %s: %v", name, err)
+	}
+
+	testnetNodes := make([]*TestnetNode, nodes)
+	for i := 0; i < nodes; i++ {
+		nodeName := fmt.Sprintf("node%d", i)
+		log.WithField("node", nodeName).Info("Making Testnet Node")
+
+		var dir, listenAddress string
+		if len(opts.Hostnames) == nodes {
+			dir = filepath.Join(outputDir, opts.Hostnames[i])
+			listenAddress = fmt.Sprintf("%s:%d", opts.Hostnames[i], basePort)
+		} else {
+			dir = filepath.Join(outputDir, nodeName)
+			listenAddress = fmt.Sprintf("%s.%d:%d", subnet, i+2, basePort)
+		}
+
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("Failed to create node directory %s: %v", dir, err)
+		}
+
+		nodeID, nodeKeyJSON, err := newNodeKey()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to generate node key for %s: %v", nodeName, err)
+		}
+		if err = ioutil.WriteFile(filepath.Join(dir, "node_key.json"), nodeKeyJSON, 0600); err != nil {
+			return nil, err
+		}
+
+		if err = writeValidatorSigningFile(dir, validatorConstructors[i], opts.Unsafe); err != nil {
+			return nil, err
+		}
+
+		if err = ioutil.WriteFile(filepath.Join(dir, "genesis.json"), genesisBytes, 0644); err != nil {
+			return nil, err
+		}
+
+		testnetNodes[i] = &TestnetNode{
+			Index:         i,
+			Name:          nodeName,
+			Dir:           dir,
+			ListenAddress: listenAddress,
+			NodeID:        nodeID,
+		}
+	}
+
+	// now that every node has an ID and listen address, wire each node's
+	// config.toml persistent_peers to every *other* node.
+	for i, node := range testnetNodes {
+		peers := make([]string, 0, nodes-1)
+		for j, peer := range testnetNodes {
+			if j != i {
+				peers = append(peers, fmt.Sprintf("%s@%s", peer.NodeID, peer.ListenAddress))
+			}
+		}
+		if err = writeNodeConfig(node.Dir, name, node.ListenAddress, peers); err != nil {
+			return nil, fmt.Errorf("Failed to write config.toml for %s: %v", node.Name, err)
+		}
+	}
+
+	if opts.EmitDockerCompose {
+		if err = writeDockerCompose(outputDir, name, subnet, testnetNodes, basePort); err != nil {
+			return nil, fmt.Errorf("Failed to write docker-compose.yml for testnet %s: %v", name, err)
+		}
+	}
+
+	return testnetNodes, nil
+}
+
+// newNodeKey generates a fresh ed25519 node identity and returns both its
+// Tendermint-style node ID (the hex ripemd160 of its public key, the same
+// address hash this package already uses for ed25519 accounts) and the
+// node_key.json bytes to write to disk.
+func newNodeKey() (nodeID string, nodeKeyJSON []byte, err error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", nil, err
+	}
+
+	hash := ripemd160.New()
+	hash.Write(publicKey)
+	nodeID = hex.EncodeToString(hash.Sum(nil))
+
+	nodeKeyJSON, err = json.MarshalIndent(map[string]interface{}{
+		"priv_key": map[string]string{
+			"type":  "ed25519",
+			"value": base64.StdEncoding.EncodeToString(privateKey),
+		},
+	}, "", "  ")
+	return nodeID, nodeKeyJSON, err
+}
+
+// writeValidatorSigningFile writes whatever this node needs to sign as a
+// validator: priv_validator.json if the key was extracted locally
+// (eris-keys, --unsafe), or otherwise a small remote-signer stub holding
+// the validator's address, so the private key is never written to disk
+// here.
+func writeValidatorSigningFile(dir string, validator *ErisDBAccountConstructor, unsafe bool) error {
+	if unsafe && validator.genesisPrivateValidator != nil {
+		privValidatorJSON, err := json.MarshalIndent(validator.genesisPrivateValidator, "", "  ")
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(dir, "priv_validator.json"), privValidatorJSON, 0600)
+	}
+
+	stub, err := json.MarshalIndent(map[string]string{
+		"address":        hex.EncodeToString(validator.genesisAccount.Address),
+		"signer_backend": "remote",
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "priv_validator.json"), stub, 0600)
+}
+
+// writeNodeConfig writes a minimal config.toml for one node: its chain
+// ID, p2p listen address, and the persistent_peers of every other node.
+func writeNodeConfig(dir, chainID, listenAddress string, peers []string) error {
+	config := fmt.Sprintf(
+		"chain_id = %q\n\n[p2p]\nladdr = \"tcp://%s\"\npersistent_peers = %q\n",
+		chainID, listenAddress, joinPeers(peers))
+	return ioutil.WriteFile(filepath.Join(dir, "config.toml"), []byte(config), 0644)
+}
+
+func joinPeers(peers []string) string {
+	joined := ""
+	for i, peer := range peers {
+		if i > 0 {
+			joined += ","
+		}
+		joined += peer
+	}
+	return joined
+}
+
+// writeDockerCompose writes a docker-compose.yml to outputDir that brings
+// up every colocated node as its own service, bound to the IP it was
+// assigned on the testnet subnet and publishing its p2p port on the host
+// as basePort plus its node index.
+func writeDockerCompose(outputDir, name, subnet string, nodes []*TestnetNode, basePort int) error {
+	compose := fmt.Sprintf("version: \"3\"\nservices:\n")
+	for _, node := range nodes {
+		ip, _, err := net.SplitHostPort(node.ListenAddress)
+		if err != nil {
+			return err
+		}
+		compose += fmt.Sprintf(
+			"  %s_%s:\n    image: quay.io/eris/eris-db\n    volumes:\n      - ./%s:/home/eris/.eris/chains/%s\n    ports:\n      - \"%d:%d\"\n    networks:\n      testnet:\n        ipv4_address: %s\n",
+			name, node.Name, node.Name, name, basePort+node.Index, basePort, ip)
+	}
+	compose += fmt.Sprintf("networks:\n  testnet:\n    ipam:\n      config:\n        - subnet: %s.0/24\n", subnet)
+	return ioutil.WriteFile(filepath.Join(outputDir, "docker-compose.yml"), []byte(compose), 0644)
+}
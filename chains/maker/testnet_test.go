@@ -0,0 +1,13 @@
+package maker
+
+import "testing"
+
+func TestMakeTestnetRejectsDockerComposeWithHostnames(t *testing.T) {
+	_, err := MakeTestnet("testchain", 2, nil, TestnetOptions{
+		Hostnames:         []string{"node-a", "node-b"},
+		EmitDockerCompose: true,
+	})
+	if err != errDockerComposeNeedsColocatedLayout {
+		t.Fatalf("err = %v, want errDockerComposeNeedsColocatedLayout", err)
+	}
+}